@@ -21,8 +21,9 @@ func main() {
 		port = "8080"
 	}
 
-	// Register the handler
+	// Register the handlers
 	http.HandleFunc("/", backend.ChatWithHistory)
+	http.HandleFunc("/index", backend.IndexHistory)
 
 	log.Printf("Starting local server on port %s...", port)
 	log.Printf("Server will be available at: http://localhost:%s", port)
@@ -33,4 +34,3 @@ func main() {
 		log.Fatalf("Server failed to start: %v\n", err)
 	}
 }
-
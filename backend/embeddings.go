@@ -0,0 +1,229 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	embeddingModel                = "text-embedding-3-small"
+	defaultEmbeddingBatchSize     = 100
+	defaultEmbeddingMaxRetries    = 5
+	defaultEmbeddingRetryBaseWait = 500 * time.Millisecond
+)
+
+// openAIEmbeddingsURL is a var (not a const) so tests can point it at an
+// httptest.Server to exercise the retry/backoff logic without a live key.
+var openAIEmbeddingsURL = "https://api.openai.com/v1/embeddings"
+
+// embeddingBatchSize returns how many texts to embed per /v1/embeddings
+// request, configurable via EMBEDDING_BATCH_SIZE for deployments embedding
+// against a provider with different batch limits.
+func embeddingBatchSize() int {
+	return envInt("EMBEDDING_BATCH_SIZE", defaultEmbeddingBatchSize)
+}
+
+// embeddingMaxRetries returns how many attempts in total (the first request
+// included) to make at a rate-limited batch before giving up, configurable
+// via EMBEDDING_MAX_RETRIES.
+func embeddingMaxRetries() int {
+	return envInt("EMBEDDING_MAX_RETRIES", defaultEmbeddingMaxRetries)
+}
+
+// embeddingRetryBaseWait returns the base delay doubled on each retry,
+// configurable via EMBEDDING_RETRY_BASE_WAIT (a Go duration string, e.g.
+// "500ms").
+func embeddingRetryBaseWait() time.Duration {
+	raw := os.Getenv("EMBEDDING_RETRY_BASE_WAIT")
+	if raw == "" {
+		return defaultEmbeddingRetryBaseWait
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return defaultEmbeddingRetryBaseWait
+	}
+	return d
+}
+
+// envInt reads key as an int, falling back to def if it's unset or
+// unparseable.
+func envInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Embedder turns text into vectors for similarity search. OpenAIEmbedder is
+// the only implementation today, but the interface keeps HistoryIndex
+// independent of a specific embeddings provider.
+type Embedder interface {
+	Embed(texts []string) ([][]float32, error)
+}
+
+// OpenAIEmbedder embeds text via OpenAI's /v1/embeddings endpoint.
+type OpenAIEmbedder struct {
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder using the default embedding model.
+func NewOpenAIEmbedder(apiKey string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		APIKey: apiKey,
+		Model:  embeddingModel,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// OpenAIEmbeddingRequest represents the request body for /v1/embeddings.
+type OpenAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// OpenAIEmbeddingData represents a single embedding in the response.
+type OpenAIEmbeddingData struct {
+	Embedding []float32 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+
+// OpenAIEmbeddingResponse represents the response body from /v1/embeddings.
+type OpenAIEmbeddingResponse struct {
+	Data []OpenAIEmbeddingData `json:"data"`
+}
+
+// Embed batches texts into groups of embeddingBatchSize() and requests
+// vectors for each batch, retrying with exponential backoff when OpenAI
+// responds with 429. The returned slice preserves the order of texts.
+func (e *OpenAIEmbedder) Embed(texts []string) ([][]float32, error) {
+	if e.APIKey == "" {
+		return nil, fmt.Errorf("OpenAI API key not configured")
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	batchSize := embeddingBatchSize()
+
+	for start := 0; start < len(texts); start += batchSize {
+		end := start + batchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		batch, err := e.embedBatch(texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed batch starting at %d: %w", start, err)
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	return vectors, nil
+}
+
+// embedBatch embeds a single batch, retrying on 429 with exponential backoff.
+func (e *OpenAIEmbedder) embedBatch(texts []string) ([][]float32, error) {
+	var lastErr error
+	maxRetries := embeddingMaxRetries()
+	baseWait := embeddingRetryBaseWait()
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(baseWait * time.Duration(1<<uint(attempt-1)))
+		}
+
+		vectors, retryable, err := e.doEmbedBatch(texts)
+		if err == nil {
+			return vectors, nil
+		}
+		lastErr = err
+		if !retryable {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("exceeded retries: %w", lastErr)
+}
+
+// doEmbedBatch makes a single request. The retryable bool reports whether
+// the caller should back off and try again (true for 429s).
+func (e *OpenAIEmbedder) doEmbedBatch(texts []string) ([][]float32, bool, error) {
+	requestBody, err := json.Marshal(OpenAIEmbeddingRequest{Model: e.Model, Input: texts})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", openAIEmbeddingsURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", e.APIKey))
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, true, fmt.Errorf("OpenAI embeddings rate limit exceeded")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var openAIErr OpenAIError
+		if err := json.Unmarshal(body, &openAIErr); err == nil && openAIErr.Error.Message != "" {
+			return nil, false, fmt.Errorf("OpenAI API error: %s", openAIErr.Error.Message)
+		}
+		return nil, false, fmt.Errorf("OpenAI API error: status %d", resp.StatusCode)
+	}
+
+	var embeddingResp OpenAIEmbeddingResponse
+	if err := json.Unmarshal(body, &embeddingResp); err != nil {
+		return nil, false, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	vectors := make([][]float32, len(embeddingResp.Data))
+	for _, d := range embeddingResp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			continue
+		}
+		vectors[d.Index] = d.Embedding
+	}
+
+	return vectors, false, nil
+}
+
+// cosineSimilarity returns the cosine similarity of two equal-length
+// vectors, or 0 if either is a zero vector.
+func cosineSimilarity(a, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,172 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// restoreEmbeddingsURL points openAIEmbeddingsURL at url for the duration of
+// the test, restoring the original value on cleanup.
+func restoreEmbeddingsURL(t *testing.T, url string) {
+	t.Helper()
+	original := openAIEmbeddingsURL
+	openAIEmbeddingsURL = url
+	t.Cleanup(func() { openAIEmbeddingsURL = original })
+}
+
+func TestOpenAIEmbedderEmbed(t *testing.T) {
+	t.Run("requires an API key", func(t *testing.T) {
+		e := &OpenAIEmbedder{}
+		if _, err := e.Embed([]string{"hello"}); err == nil {
+			t.Fatal("expected an error when APIKey is empty, got nil")
+		}
+	})
+
+	t.Run("retries on 429 and succeeds once the server recovers", func(t *testing.T) {
+		t.Setenv("EMBEDDING_RETRY_BASE_WAIT", "1ms")
+		var attempts int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) <= 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			var req OpenAIEmbeddingRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("failed to decode request: %v", err)
+			}
+
+			resp := OpenAIEmbeddingResponse{Data: make([]OpenAIEmbeddingData, len(req.Input))}
+			for i := range req.Input {
+				resp.Data[i] = OpenAIEmbeddingData{Embedding: []float32{float32(i)}, Index: i}
+			}
+			json.NewEncoder(w).Encode(resp)
+		}))
+		defer server.Close()
+
+		restoreEmbeddingsURL(t, server.URL)
+		e := &OpenAIEmbedder{APIKey: "test-key", Model: embeddingModel, HTTPClient: server.Client()}
+		vectors, err := e.Embed([]string{"a", "b"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(vectors) != 2 {
+			t.Fatalf("got %d vectors, want 2", len(vectors))
+		}
+
+		if got := atomic.LoadInt32(&attempts); got != 3 {
+			t.Errorf("server got %d attempts, want 3 (two 429s then a success)", got)
+		}
+	})
+
+	t.Run("gives up and returns an error after exhausting retries", func(t *testing.T) {
+		t.Setenv("EMBEDDING_RETRY_BASE_WAIT", "1ms")
+		t.Setenv("EMBEDDING_MAX_RETRIES", "2")
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		restoreEmbeddingsURL(t, server.URL)
+		e := &OpenAIEmbedder{APIKey: "test-key", Model: embeddingModel, HTTPClient: server.Client()}
+		if _, err := e.Embed([]string{"a"}); err == nil {
+			t.Fatal("expected an error after exhausting retries, got nil")
+		}
+		if got := atomic.LoadInt32(&attempts); got != 2 {
+			t.Errorf("server got %d attempts, want 2 (EMBEDDING_MAX_RETRIES)", got)
+		}
+	})
+
+	t.Run("non-retryable errors return immediately", func(t *testing.T) {
+		var attempts int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(OpenAIError{})
+		}))
+		defer server.Close()
+
+		restoreEmbeddingsURL(t, server.URL)
+		e := &OpenAIEmbedder{APIKey: "test-key", Model: embeddingModel, HTTPClient: server.Client()}
+		if _, err := e.Embed([]string{"a"}); err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("server got %d attempts, want 1 (non-retryable errors shouldn't be retried)", got)
+		}
+	})
+}
+
+func TestEmbeddingConfig(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		if got := embeddingBatchSize(); got != defaultEmbeddingBatchSize {
+			t.Errorf("embeddingBatchSize() = %d, want %d", got, defaultEmbeddingBatchSize)
+		}
+		if got := embeddingMaxRetries(); got != defaultEmbeddingMaxRetries {
+			t.Errorf("embeddingMaxRetries() = %d, want %d", got, defaultEmbeddingMaxRetries)
+		}
+		if got := embeddingRetryBaseWait(); got != defaultEmbeddingRetryBaseWait {
+			t.Errorf("embeddingRetryBaseWait() = %v, want %v", got, defaultEmbeddingRetryBaseWait)
+		}
+	})
+
+	t.Run("env vars override the defaults", func(t *testing.T) {
+		t.Setenv("EMBEDDING_BATCH_SIZE", "25")
+		t.Setenv("EMBEDDING_MAX_RETRIES", "2")
+		t.Setenv("EMBEDDING_RETRY_BASE_WAIT", "10ms")
+
+		if got := embeddingBatchSize(); got != 25 {
+			t.Errorf("embeddingBatchSize() = %d, want 25", got)
+		}
+		if got := embeddingMaxRetries(); got != 2 {
+			t.Errorf("embeddingMaxRetries() = %d, want 2", got)
+		}
+		if got := embeddingRetryBaseWait(); got != 10*time.Millisecond {
+			t.Errorf("embeddingRetryBaseWait() = %v, want 10ms", got)
+		}
+	})
+
+	t.Run("unparseable values fall back to the defaults", func(t *testing.T) {
+		t.Setenv("EMBEDDING_BATCH_SIZE", "not-a-number")
+		t.Setenv("EMBEDDING_RETRY_BASE_WAIT", "not-a-duration")
+
+		if got := embeddingBatchSize(); got != defaultEmbeddingBatchSize {
+			t.Errorf("embeddingBatchSize() = %d, want default %d", got, defaultEmbeddingBatchSize)
+		}
+		if got := embeddingRetryBaseWait(); got != defaultEmbeddingRetryBaseWait {
+			t.Errorf("embeddingRetryBaseWait() = %v, want default %v", got, defaultEmbeddingRetryBaseWait)
+		}
+	})
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float32
+	}{
+		{name: "identical vectors", a: []float32{1, 2, 3}, b: []float32{1, 2, 3}, want: 1},
+		{name: "opposite vectors", a: []float32{1, 0}, b: []float32{-1, 0}, want: -1},
+		{name: "orthogonal vectors", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "zero vector", a: []float32{0, 0}, b: []float32{1, 1}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cosineSimilarity(tt.a, tt.b)
+			if diff := got - tt.want; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("cosineSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
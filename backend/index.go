@@ -0,0 +1,227 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+// retrievalTopK is how many history entries are pulled into the prompt for
+// a single chat message, replacing the old "first 50 entries" truncation.
+const retrievalTopK = 20
+
+// selectRelevantHistory narrows history down to the entries most relevant
+// to query using the package-level retrieval index, falling back to a
+// simple truncation if the index isn't available or retrieval fails (e.g.
+// no OpenAI API key, or this is the first message before anything has been
+// indexed).
+func selectRelevantHistory(query string, history []HistoryEntry) []HistoryEntry {
+	if historyIndex == nil || len(history) == 0 {
+		return truncateHistory(history, retrievalTopK)
+	}
+
+	if _, err := historyIndex.Ingest(history); err != nil {
+		log.Printf("WARNING: failed to index history for retrieval: %v", err)
+		return truncateHistory(history, retrievalTopK)
+	}
+
+	relevant, err := historyIndex.Retrieve(query, retrievalTopK)
+	if err != nil || len(relevant) == 0 {
+		if err != nil {
+			log.Printf("WARNING: retrieval failed, falling back to truncation: %v", err)
+		}
+		return truncateHistory(history, retrievalTopK)
+	}
+
+	return relevant
+}
+
+// truncateHistory returns at most the first n entries, used when retrieval
+// isn't available.
+func truncateHistory(history []HistoryEntry, n int) []HistoryEntry {
+	if len(history) <= n {
+		return history
+	}
+	return history[:n]
+}
+
+// IndexedEntry is a HistoryEntry enriched with an embedding vector so it can
+// be ranked by relevance instead of dumped wholesale into the prompt.
+type IndexedEntry struct {
+	URL        string
+	Title      string
+	Vector     []float32
+	LastVisit  int64
+	VisitCount int
+}
+
+// HistoryIndex holds an in-memory, cosine-similarity index over browsing
+// history, backed by an optional gob file so re-indexing on the next run is
+// incremental instead of re-embedding everything.
+type HistoryIndex struct {
+	mu       sync.RWMutex
+	entries  map[string]IndexedEntry // keyed by hash of URL+title
+	embedder Embedder
+	path     string
+}
+
+// NewHistoryIndex creates a HistoryIndex that embeds via embedder and
+// persists to path. An empty path disables persistence (in-memory only).
+func NewHistoryIndex(embedder Embedder, path string) *HistoryIndex {
+	return &HistoryIndex{
+		entries:  make(map[string]IndexedEntry),
+		embedder: embedder,
+		path:     path,
+	}
+}
+
+// entryKey hashes URL+title so incremental re-indexing can detect entries
+// it has already embedded without storing the raw text as a map key.
+func entryKey(url, title string) string {
+	sum := sha256.Sum256([]byte(url + "\x00" + title))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads a previously persisted index from disk. A missing file is not
+// an error - it just means this is the first run.
+func (idx *HistoryIndex) Load() error {
+	if idx.path == "" {
+		return nil
+	}
+
+	f, err := os.Open(idx.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open index file: %w", err)
+	}
+	defer f.Close()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if err := gob.NewDecoder(f).Decode(&idx.entries); err != nil {
+		return fmt.Errorf("failed to decode index file: %w", err)
+	}
+	return nil
+}
+
+// save persists the index to disk. Called after Ingest so a crash never
+// loses more than the most recent batch. Must be called with idx.mu held.
+func (idx *HistoryIndex) save() error {
+	if idx.path == "" {
+		return nil
+	}
+
+	f, err := os.Create(idx.path)
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer f.Close()
+
+	if err := gob.NewEncoder(f).Encode(idx.entries); err != nil {
+		return fmt.Errorf("failed to encode index file: %w", err)
+	}
+	return nil
+}
+
+// Ingest embeds and stores any entries not already indexed, leaving
+// previously embedded entries untouched so repeated ingests of the same
+// history are cheap. It returns the number of new entries that were
+// embedded, which is 0 (and no error) when everything passed in was already
+// indexed.
+func (idx *HistoryIndex) Ingest(entries []HistoryEntry) (int, error) {
+	idx.mu.RLock()
+	toEmbed := make([]HistoryEntry, 0, len(entries))
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		key := entryKey(entry.URL, entry.Title)
+		if _, exists := idx.entries[key]; exists {
+			continue
+		}
+		toEmbed = append(toEmbed, entry)
+		keys = append(keys, key)
+	}
+	idx.mu.RUnlock()
+
+	if len(toEmbed) == 0 {
+		return 0, nil
+	}
+
+	texts := make([]string, len(toEmbed))
+	for i, entry := range toEmbed {
+		texts[i] = entry.Title + " " + entry.URL
+	}
+
+	vectors, err := idx.embedder.Embed(texts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to embed history entries: %w", err)
+	}
+
+	idx.mu.Lock()
+	for i, entry := range toEmbed {
+		idx.entries[keys[i]] = IndexedEntry{
+			URL:        entry.URL,
+			Title:      entry.Title,
+			Vector:     vectors[i],
+			LastVisit:  entry.LastVisitTime,
+			VisitCount: entry.VisitCount,
+		}
+	}
+	err = idx.save()
+	idx.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+	return len(toEmbed), nil
+}
+
+// Retrieve embeds query and returns the k most similar indexed entries,
+// most relevant first.
+func (idx *HistoryIndex) Retrieve(query string, k int) ([]HistoryEntry, error) {
+	vectors, err := idx.embedder.Embed([]string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	queryVector := vectors[0]
+
+	type scored struct {
+		entry IndexedEntry
+		score float32
+	}
+
+	idx.mu.RLock()
+	candidates := make([]scored, 0, len(idx.entries))
+	for _, entry := range idx.entries {
+		candidates = append(candidates, scored{entry: entry, score: cosineSimilarity(queryVector, entry.Vector)})
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	results := make([]HistoryEntry, k)
+	for i := 0; i < k; i++ {
+		e := candidates[i].entry
+		results[i] = HistoryEntry{
+			URL:           e.URL,
+			Title:         e.Title,
+			VisitCount:    e.VisitCount,
+			LastVisitTime: e.LastVisit,
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// IndexRequest is the body POSTed to IndexHistory to incrementally add
+// entries to the retrieval index.
+type IndexRequest struct {
+	History []HistoryEntry `json:"history"`
+}
+
+// IndexResponse reports how many new entries were embedded.
+type IndexResponse struct {
+	Indexed int    `json:"indexed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// IndexHistory lets the extension push history to the retrieval index
+// incrementally instead of re-sending the full history on every chat
+// request. Entries already embedded are skipped, so repeated calls are
+// cheap.
+func IndexHistory(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		sendJSONResponse(w, http.StatusMethodNotAllowed, IndexResponse{Error: "Only POST method is allowed"})
+		return
+	}
+
+	ip := getClientIP(r)
+	if !rateLimiter.Allow(ip) {
+		sendJSONResponse(w, http.StatusTooManyRequests, IndexResponse{Error: "Rate limit exceeded. Please try again later."})
+		return
+	}
+
+	var req IndexRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, IndexResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if openAIClient.APIKey == "" {
+		sendJSONResponse(w, http.StatusInternalServerError, IndexResponse{Error: "Backend not properly configured"})
+		return
+	}
+
+	indexed, err := historyIndex.Ingest(req.History)
+	if err != nil {
+		log.Printf("Failed to index history for IP %s: %v", ip, err)
+		sendJSONResponse(w, http.StatusInternalServerError, IndexResponse{Error: fmt.Sprintf("Failed to index history: %v", err)})
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, IndexResponse{Indexed: indexed})
+}
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,9 +10,18 @@ import (
 	"time"
 )
 
+// requestTimeout bounds how long ChatWithHistory will wait on an upstream
+// LLM provider before giving up, regardless of the caller's own deadline.
+const requestTimeout = 55 * time.Second
+
 var (
 	openAIClient *OpenAIClient
 	rateLimiter  *RateLimiter
+	historyIndex *HistoryIndex
+
+	azureProvider     ChatProvider
+	anthropicProvider ChatProvider
+	ollamaProvider    ChatProvider
 )
 
 func init() {
@@ -26,6 +36,22 @@ func init() {
 	rateLimiter = NewRateLimiter(10.0/60.0, 5)
 	rateLimiter.StartCleanup(10 * time.Minute)
 
+	// Initialize the retrieval index over browsing history. HISTORY_INDEX_PATH
+	// is optional; without it the index is in-memory only and re-embeds on
+	// every restart.
+	historyIndex = NewHistoryIndex(NewOpenAIEmbedder(apiKey), os.Getenv("HISTORY_INDEX_PATH"))
+	if err := historyIndex.Load(); err != nil {
+		log.Printf("WARNING: failed to load history index: %v", err)
+	}
+
+	// Initialize the non-default LLM backends (see ChatProvider). They're
+	// no-ops until their env vars are set and simply return a configuration
+	// error if selected before then; "openai" itself is handled directly by
+	// openAIClient rather than through a ChatProvider.
+	azureProvider = NewAzureProvider(os.Getenv("AZURE_OPENAI_ENDPOINT"), os.Getenv("AZURE_OPENAI_API_KEY"), os.Getenv("AZURE_OPENAI_DEPLOYMENT"), os.Getenv("AZURE_OPENAI_API_VERSION"))
+	anthropicProvider = NewAnthropicProvider(os.Getenv("ANTHROPIC_API_KEY"))
+	ollamaProvider = NewOllamaProvider(os.Getenv("OLLAMA_HOST"))
+
 	log.Println("Cloud Function initialized successfully")
 }
 
@@ -95,11 +121,41 @@ func ChatWithHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateChatRequest(&req); err != nil {
+		sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Log request (without sensitive data)
-	log.Printf("Processing chat request from IP: %s, history entries: %d", ip, len(req.History))
+	log.Printf("Processing chat request from IP: %s, history entries: %d, provider: %s", ip, len(req.History), providerName(&req))
+
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+
+	// Non-default providers don't get the rich OpenAI tool-calling loop (see
+	// ChatProvider); they use a single retrieval-stuffed turn instead.
+	if providerName(&req) != "openai" {
+		reply, err := sendChatViaProvider(ctx, &req)
+		if err != nil {
+			log.Printf("provider error: %v", err)
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get response: %v", err))
+			return
+		}
+		log.Printf("Successfully processed request for IP: %s", ip)
+		sendJSONResponse(w, http.StatusOK, ChatResponse{Reply: reply})
+		return
+	}
+
+	// o1-series models don't support streaming; silently fall back to a
+	// single JSON response rather than rejecting the request.
+	if req.wantsStream() && !isO1Model(resolveModel(&req)) {
+		streamChatResponse(ctx, w, &req, ip)
+		return
+	}
 
 	// Call OpenAI API
-	reply, err := openAIClient.SendChatMessage(req.Message, req.History)
+	reply, rateLimitHeaders, err := openAIClient.SendChatMessageWithHeaders(ctx, &req)
+	rateLimiter.TightenForHeaders(rateLimitHeaders)
 	if err != nil {
 		log.Printf("OpenAI API error: %v", err)
 		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get response: %v", err))
@@ -108,6 +164,98 @@ func ChatWithHistory(w http.ResponseWriter, r *http.Request) {
 
 	// Send successful response
 	log.Printf("Successfully processed request for IP: %s", ip)
-	sendJSONResponse(w, http.StatusOK, ChatResponse{Reply: reply})
+	setRateLimitHeaders(w, rateLimitHeaders)
+	sendJSONResponse(w, http.StatusOK, ChatResponse{Reply: reply, RateLimit: rateLimitHeaders})
+}
+
+// providerName returns the provider req resolves to, applying the same
+// defaulting resolveProvider does, without needing a live ChatProvider value.
+func providerName(req *ChatRequest) string {
+	if req.Provider != "" {
+		return req.Provider
+	}
+	return defaultProviderName()
 }
 
+// sendChatViaProvider runs a single chat turn through whichever ChatProvider
+// req.Provider selects, using a retrieval-stuffed system prompt since only
+// the default OpenAI path gets the history tool-calling loop.
+func sendChatViaProvider(ctx context.Context, req *ChatRequest) (string, error) {
+	provider, err := resolveProvider(req)
+	if err != nil {
+		return "", err
+	}
+
+	historyContext := formatHistoryForLLM(selectRelevantHistory(req.Message, req.History))
+	systemPrompt := buildSystemPrompt(historyContext)
+
+	reply, _, err := provider.SendChatMessage(ctx, systemPrompt, req.Message, chatParamsFromRequest(req))
+	return reply, err
+}
+
+// setRateLimitHeaders forwards OpenAI's quota headers to the extension as
+// X-RateLimit-* so clients can back off proactively instead of guessing.
+func setRateLimitHeaders(w http.ResponseWriter, headers *RateLimitHeaders) {
+	if headers == nil {
+		return
+	}
+	w.Header().Set("X-RateLimit-Limit-Requests", fmt.Sprintf("%d", headers.LimitRequests))
+	w.Header().Set("X-RateLimit-Limit-Tokens", fmt.Sprintf("%d", headers.LimitTokens))
+	w.Header().Set("X-RateLimit-Remaining-Requests", fmt.Sprintf("%d", headers.RemainingRequests))
+	w.Header().Set("X-RateLimit-Remaining-Tokens", fmt.Sprintf("%d", headers.RemainingTokens))
+	w.Header().Set("X-RateLimit-Reset-Requests", headers.ResetRequests.String())
+	w.Header().Set("X-RateLimit-Reset-Tokens", headers.ResetTokens.String())
+}
+
+// streamChatResponse upgrades the connection to text/event-stream and relays
+// each StreamChunk from OpenAI to the client as a "data: {...}" event. Falls
+// back to a single JSON error response if the client can't be flushed or the
+// upstream call fails before any chunk is sent.
+func streamChatResponse(ctx context.Context, w http.ResponseWriter, req *ChatRequest, ip string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendErrorResponse(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	chunks, rateLimitHeaders, err := openAIClient.SendChatMessageStream(ctx, req)
+	rateLimiter.TightenForHeaders(rateLimitHeaders)
+	if err != nil {
+		log.Printf("OpenAI API error: %v", err)
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get response: %v", err))
+		return
+	}
+
+	setRateLimitHeaders(w, rateLimitHeaders)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			log.Printf("Stream error for IP %s: %v", ip, chunk.Err)
+			fmt.Fprintf(w, "data: %s\n\n", mustMarshal(ChatResponse{Error: chunk.Err.Error()}))
+			flusher.Flush()
+			return
+		}
+
+		if chunk.Done {
+			fmt.Fprintf(w, "data: [DONE]\n\n")
+			flusher.Flush()
+			return
+		}
+
+		fmt.Fprintf(w, "data: %s\n\n", mustMarshal(ChatResponse{Reply: chunk.Content}))
+		flusher.Flush()
+	}
+
+	log.Printf("Successfully streamed response for IP: %s", ip)
+}
+
+// mustMarshal marshals v to JSON for SSE payloads. Encoding a ChatResponse
+// cannot fail, so errors are not propagated to the caller.
+func mustMarshal(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// isO1Model reports whether model belongs to OpenAI's o1 reasoning series,
+// which has different generation-parameter constraints than chat models.
+func isO1Model(model string) bool {
+	return strings.HasPrefix(model, "o1")
+}
+
+// allowedModels returns the set of models permitted by the ALLOWED_MODELS
+// env var (comma-separated). A nil map means no allowlist is configured, so
+// any model is accepted.
+func allowedModels() map[string]bool {
+	raw := os.Getenv("ALLOWED_MODELS")
+	if raw == "" {
+		return nil
+	}
+
+	models := make(map[string]bool)
+	for _, m := range strings.Split(raw, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models[m] = true
+		}
+	}
+	return models
+}
+
+// resolveModel returns the model to use for req, falling back to the
+// backend's default when req doesn't specify one.
+func resolveModel(req *ChatRequest) string {
+	if req.Model == "" {
+		return openAIModel
+	}
+	return req.Model
+}
+
+// validateChatRequest checks req against the configured model allowlist
+// and, for o1-series models, OpenAI's beta constraints: max_tokens is
+// rejected in favor of max_completion_tokens, temperature/top_p/n/penalties
+// must be left at their defaults, and system-role messages aren't allowed
+// (the system prompt is folded into the user message instead - see
+// buildMessages).
+//
+// Both the allowlist and the o1-series constraints are OpenAI-specific, so
+// they're only enforced when req resolves to the "openai" provider; other
+// providers pick their own default model (see e.g. AnthropicProvider) and
+// have no o1-equivalent beta restrictions to check here.
+func validateChatRequest(req *ChatRequest) error {
+	if providerName(req) != "openai" {
+		return nil
+	}
+
+	model := resolveModel(req)
+
+	if allowed := allowedModels(); allowed != nil && !allowed[model] {
+		return fmt.Errorf("model %q is not in the allowed models list", model)
+	}
+
+	if !isO1Model(model) {
+		return nil
+	}
+
+	if req.MaxTokens != nil {
+		return fmt.Errorf("max_tokens is not supported for o1-series models; use max_completion_tokens")
+	}
+	if req.Temperature != nil && *req.Temperature != 1 {
+		return fmt.Errorf("temperature is not configurable for o1-series models")
+	}
+	if req.TopP != nil && *req.TopP != 1 {
+		return fmt.Errorf("top_p is not configurable for o1-series models")
+	}
+	if req.N != nil && *req.N != 1 {
+		return fmt.Errorf("n is not configurable for o1-series models")
+	}
+	if req.PresencePenalty != nil && *req.PresencePenalty != 0 {
+		return fmt.Errorf("presence_penalty is not configurable for o1-series models")
+	}
+	if req.FrequencyPenalty != nil && *req.FrequencyPenalty != 0 {
+		return fmt.Errorf("frequency_penalty is not configurable for o1-series models")
+	}
+
+	return nil
+}
+
+// buildMessages assembles the message list for model. o1-series models
+// don't accept a system-role message, so the system prompt is folded into
+// the single user message instead of being rejected outright.
+func buildMessages(model, systemPrompt, userMessage string) []OpenAIMessage {
+	if isO1Model(model) {
+		return []OpenAIMessage{
+			{Role: "user", Content: systemPrompt + "\n\n" + userMessage},
+		}
+	}
+
+	return []OpenAIMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	}
+}
+
+// buildOpenAIRequest turns a ChatRequest plus its resolved system prompt
+// into the OpenAI API request, applying the o1-series constraints enforced
+// by validateChatRequest.
+func buildOpenAIRequest(req *ChatRequest, systemPrompt string) OpenAIRequest {
+	return buildOpenAIRequestFromParams(chatParamsFromRequest(req), systemPrompt, req.Message)
+}
+
+// buildOpenAIRequestFromParams is the provider-agnostic core of
+// buildOpenAIRequest: it applies the backend's defaults for any generation
+// parameter params didn't override, picking the o1-series shape (no system
+// message, max_completion_tokens instead of max_tokens) when appropriate.
+func buildOpenAIRequestFromParams(params ChatParams, systemPrompt, userMessage string) OpenAIRequest {
+	model := params.Model
+	if model == "" {
+		model = openAIModel
+	}
+
+	out := OpenAIRequest{
+		Model:    model,
+		Messages: buildMessages(model, systemPrompt, userMessage),
+		Seed:     params.Seed,
+	}
+
+	if params.ResponseFormat != nil {
+		out.ResponseFormat = params.ResponseFormat
+	}
+
+	if isO1Model(model) {
+		completionTokens := maxTokens
+		if params.MaxCompletionTokens != nil {
+			completionTokens = *params.MaxCompletionTokens
+		}
+		out.MaxCompletionTokens = &completionTokens
+		return out
+	}
+
+	temp := temperature
+	if params.Temperature != nil {
+		temp = *params.Temperature
+	}
+	out.Temperature = &temp
+
+	tokens := maxTokens
+	if params.MaxTokens != nil {
+		tokens = *params.MaxTokens
+	}
+	out.MaxTokens = &tokens
+
+	out.TopP = params.TopP
+	out.N = params.N
+	out.PresencePenalty = params.PresencePenalty
+	out.FrequencyPenalty = params.FrequencyPenalty
+
+	return out
+}
@@ -0,0 +1,90 @@
+package backend
+
+import "testing"
+
+func floatPtr(f float64) *float64 { return &f }
+func intPtr(i int) *int           { return &i }
+
+func TestValidateChatRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ChatRequest
+		allowed string
+		wantErr bool
+	}{
+		{name: "plain chat model", req: ChatRequest{Model: "gpt-4o-mini"}},
+		{name: "model not in allowlist", req: ChatRequest{Model: "gpt-4o-mini"}, allowed: "o1-preview,o1-mini", wantErr: true},
+		{name: "model in allowlist", req: ChatRequest{Model: "gpt-4o-mini"}, allowed: "gpt-4o-mini"},
+		{name: "o1 model with default temperature", req: ChatRequest{Model: "o1-preview", Temperature: floatPtr(1)}},
+		{name: "o1 model rejects max_tokens", req: ChatRequest{Model: "o1-preview", MaxTokens: intPtr(100)}, wantErr: true},
+		{name: "o1 model rejects non-default temperature", req: ChatRequest{Model: "o1-preview", Temperature: floatPtr(0.5)}, wantErr: true},
+		{name: "o1 model rejects non-default top_p", req: ChatRequest{Model: "o1-preview", TopP: floatPtr(0.9)}, wantErr: true},
+		{name: "o1 model rejects non-default n", req: ChatRequest{Model: "o1-preview", N: intPtr(2)}, wantErr: true},
+		{name: "o1 model rejects presence_penalty", req: ChatRequest{Model: "o1-preview", PresencePenalty: floatPtr(0.1)}, wantErr: true},
+		{name: "o1 model rejects frequency_penalty", req: ChatRequest{Model: "o1-preview", FrequencyPenalty: floatPtr(0.1)}, wantErr: true},
+		{name: "non-openai provider skips allowlist and o1 checks", req: ChatRequest{Provider: "anthropic", Model: "gpt-4o-mini"}, allowed: "o1-preview"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.allowed != "" {
+				t.Setenv("ALLOWED_MODELS", tt.allowed)
+			}
+
+			err := validateChatRequest(&tt.req)
+			if tt.wantErr && err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildOpenAIRequestFromParams(t *testing.T) {
+	t.Run("chat model applies defaults and system message", func(t *testing.T) {
+		out := buildOpenAIRequestFromParams(ChatParams{}, "system prompt", "hello")
+
+		if out.Model != openAIModel {
+			t.Errorf("Model = %q, want %q", out.Model, openAIModel)
+		}
+		if out.Temperature == nil || *out.Temperature != temperature {
+			t.Errorf("Temperature = %v, want %v", out.Temperature, temperature)
+		}
+		if out.MaxTokens == nil || *out.MaxTokens != maxTokens {
+			t.Errorf("MaxTokens = %v, want %v", out.MaxTokens, maxTokens)
+		}
+		if out.MaxCompletionTokens != nil {
+			t.Errorf("MaxCompletionTokens = %v, want nil for a non-o1 model", out.MaxCompletionTokens)
+		}
+		if len(out.Messages) != 2 || out.Messages[0].Role != "system" {
+			t.Errorf("Messages = %+v, want a leading system message", out.Messages)
+		}
+	})
+
+	t.Run("caller overrides are preserved", func(t *testing.T) {
+		out := buildOpenAIRequestFromParams(ChatParams{Temperature: floatPtr(0), MaxTokens: intPtr(42)}, "system", "hi")
+
+		if out.Temperature == nil || *out.Temperature != 0 {
+			t.Errorf("Temperature = %v, want 0 (explicit zero should survive)", out.Temperature)
+		}
+		if out.MaxTokens == nil || *out.MaxTokens != 42 {
+			t.Errorf("MaxTokens = %v, want 42", out.MaxTokens)
+		}
+	})
+
+	t.Run("o1 model uses max_completion_tokens and folds system prompt into the user message", func(t *testing.T) {
+		out := buildOpenAIRequestFromParams(ChatParams{Model: "o1-preview"}, "system prompt", "hello")
+
+		if out.MaxTokens != nil {
+			t.Errorf("MaxTokens = %v, want nil for an o1 model", out.MaxTokens)
+		}
+		if out.MaxCompletionTokens == nil || *out.MaxCompletionTokens != maxTokens {
+			t.Errorf("MaxCompletionTokens = %v, want %v", out.MaxCompletionTokens, maxTokens)
+		}
+		if len(out.Messages) != 1 || out.Messages[0].Role != "user" {
+			t.Errorf("Messages = %+v, want a single user message", out.Messages)
+		}
+	})
+}
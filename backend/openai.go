@@ -2,10 +2,12 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -33,7 +35,10 @@ func NewOpenAIClient(apiKey string) *OpenAIClient {
 	}
 }
 
-// formatHistoryForLLM converts history entries to a readable format for the LLM
+// formatHistoryForLLM converts history entries to a readable format for the
+// LLM. Callers are expected to have already narrowed history down to a
+// manageable, relevant set (see selectRelevantHistory) - this just renders
+// whatever it's given.
 func formatHistoryForLLM(history []HistoryEntry) string {
 	if len(history) == 0 {
 		return "No browsing history available."
@@ -43,15 +48,9 @@ func formatHistoryForLLM(history []HistoryEntry) string {
 	builder.WriteString("Recent browsing history:\n\n")
 
 	for i, entry := range history {
-		// Limit to first 50 entries to avoid token limits
-		if i >= 50 {
-			builder.WriteString(fmt.Sprintf("\n... and %d more entries", len(history)-50))
-			break
-		}
-
 		// Format timestamp
 		timestamp := time.Unix(entry.LastVisitTime/1000, 0).Format("2006-01-02 15:04")
-		
+
 		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, entry.Title))
 		builder.WriteString(fmt.Sprintf("   URL: %s\n", entry.URL))
 		builder.WriteString(fmt.Sprintf("   Last visited: %s (visited %d times)\n\n", timestamp, entry.VisitCount))
@@ -69,43 +68,75 @@ func buildSystemPrompt(historyContext string) string {
 Please answer the user's question based on this browsing history. Be concise and helpful. If the history doesn't contain relevant information, let the user know.`, historyContext)
 }
 
-// SendChatMessage sends a message to OpenAI with history context
-func (c *OpenAIClient) SendChatMessage(userMessage string, history []HistoryEntry) (string, error) {
+// SendChatMessageWithHeaders drives the rich tool-calling chat flow and also
+// returns the rate-limit headers OpenAI attached to the response, so callers
+// can forward quota information to the extension or feed it back into the
+// RateLimiter.
+//
+// Rather than stuffing browsing history into the system prompt, the model
+// is given history-querying tools (see historyTools) and runs a tool-dispatch
+// loop: call OpenAI, and whenever it asks for a tool, execute it locally and
+// feed the result back as a role:"tool" message, until it returns a textual
+// answer or maxToolIterations is hit.
+func (c *OpenAIClient) SendChatMessageWithHeaders(ctx context.Context, chatReq *ChatRequest) (string, *RateLimitHeaders, error) {
 	if c.APIKey == "" {
-		return "", fmt.Errorf("OpenAI API key not configured")
+		return "", nil, fmt.Errorf("OpenAI API key not configured")
 	}
 
-	// Format history for LLM
-	historyContext := formatHistoryForLLM(history)
-	systemPrompt := buildSystemPrompt(historyContext)
-
-	// Prepare request
-	request := OpenAIRequest{
-		Model:       openAIModel,
-		Temperature: temperature,
-		MaxTokens:   maxTokens,
-		Messages: []OpenAIMessage{
-			{
-				Role:    "system",
-				Content: systemPrompt,
-			},
-			{
-				Role:    "user",
-				Content: userMessage,
-			},
-		},
+	systemPrompt := buildToolSystemPrompt(len(chatReq.History))
+	request := buildOpenAIRequest(chatReq, systemPrompt)
+	request.Tools = historyTools()
+	request.ToolChoice = "auto"
+
+	var lastHeaders *RateLimitHeaders
+
+	for i := 0; i < maxToolIterations; i++ {
+		openAIResp, rateLimitHeaders, err := c.doChatCompletion(ctx, request)
+		lastHeaders = rateLimitHeaders
+		if err != nil {
+			return "", lastHeaders, err
+		}
+
+		if len(openAIResp.Choices) == 0 {
+			return "", lastHeaders, fmt.Errorf("no response from OpenAI")
+		}
+
+		choice := openAIResp.Choices[0]
+		if choice.FinishReason != "tool_calls" || len(choice.Message.ToolCalls) == 0 {
+			return choice.Message.Content, lastHeaders, nil
+		}
+
+		request.Messages = append(request.Messages, choice.Message)
+		for _, call := range choice.Message.ToolCalls {
+			result, err := dispatchToolCall(call, chatReq.History)
+			if err != nil {
+				result = fmt.Sprintf(`{"error": %q}`, err.Error())
+			}
+			request.Messages = append(request.Messages, OpenAIMessage{
+				Role:       "tool",
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
 	}
 
+	return "", lastHeaders, fmt.Errorf("exceeded %d tool-calling iterations without a final answer", maxToolIterations)
+}
+
+// doChatCompletion sends a single chat completion request and parses the
+// response, extracting rate-limit headers along the way. ctx carries the
+// caller's deadline/cancellation through to the upstream HTTP call.
+func (c *OpenAIClient) doChatCompletion(ctx context.Context, request OpenAIRequest) (*OpenAIResponse, *RateLimitHeaders, error) {
 	// Marshal request to JSON
 	requestBody, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", openAIAPIURL, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", openAIAPIURL, bytes.NewBuffer(requestBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -114,14 +145,16 @@ func (c *OpenAIClient) SendChatMessage(userMessage string, history []HistoryEntr
 	// Send request
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	rateLimitHeaders := parseRateLimitHeaders(resp.Header)
+
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, rateLimitHeaders, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Handle error responses
@@ -129,25 +162,50 @@ func (c *OpenAIClient) SendChatMessage(userMessage string, history []HistoryEntr
 		var openAIErr OpenAIError
 		if err := json.Unmarshal(body, &openAIErr); err == nil && openAIErr.Error.Message != "" {
 			if resp.StatusCode == http.StatusUnauthorized {
-				return "", fmt.Errorf("invalid OpenAI API key")
+				return nil, rateLimitHeaders, fmt.Errorf("invalid OpenAI API key")
 			} else if resp.StatusCode == http.StatusTooManyRequests {
-				return "", fmt.Errorf("OpenAI rate limit exceeded. Please try again later")
+				return nil, rateLimitHeaders, fmt.Errorf("OpenAI rate limit exceeded. Please try again later")
 			}
-			return "", fmt.Errorf("OpenAI API error: %s", openAIErr.Error.Message)
+			return nil, rateLimitHeaders, fmt.Errorf("OpenAI API error: %s", openAIErr.Error.Message)
 		}
-		return "", fmt.Errorf("OpenAI API error: status %d", resp.StatusCode)
+		return nil, rateLimitHeaders, fmt.Errorf("OpenAI API error: status %d", resp.StatusCode)
 	}
 
 	// Parse successful response
 	var openAIResp OpenAIResponse
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, rateLimitHeaders, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(openAIResp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+	return &openAIResp, rateLimitHeaders, nil
+}
+
+// parseRateLimitHeaders extracts OpenAI's x-ratelimit-* headers from a
+// response. Missing or unparseable headers are left as zero values rather
+// than failing the call, since they're informational.
+func parseRateLimitHeaders(h http.Header) *RateLimitHeaders {
+	return &RateLimitHeaders{
+		LimitRequests:     parseIntHeader(h, "x-ratelimit-limit-requests"),
+		LimitTokens:       parseIntHeader(h, "x-ratelimit-limit-tokens"),
+		RemainingRequests: parseIntHeader(h, "x-ratelimit-remaining-requests"),
+		RemainingTokens:   parseIntHeader(h, "x-ratelimit-remaining-tokens"),
+		ResetRequests:     parseDurationHeader(h, "x-ratelimit-reset-requests"),
+		ResetTokens:       parseDurationHeader(h, "x-ratelimit-reset-tokens"),
 	}
+}
 
-	return openAIResp.Choices[0].Message.Content, nil
+func parseIntHeader(h http.Header, key string) int {
+	v, err := strconv.Atoi(h.Get(key))
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
+func parseDurationHeader(h http.Header, key string) time.Duration {
+	d, err := time.ParseDuration(h.Get(key))
+	if err != nil {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	anthropicAPIURL    = "https://api.anthropic.com/v1/messages"
+	anthropicVersion   = "2023-06-01"
+	anthropicModel     = "claude-3-5-sonnet-latest"
+	anthropicMaxTokens = 1000
+)
+
+// AnthropicProvider sends chat completions to Anthropic's Messages API.
+// Unlike OpenAI, Anthropic takes the system prompt as a top-level field
+// rather than a message in the conversation, and requires max_tokens on
+// every request.
+type AnthropicProvider struct {
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewAnthropicProvider creates a provider using the given API key.
+func NewAnthropicProvider(apiKey string) *AnthropicProvider {
+	return &AnthropicProvider{
+		APIKey: apiKey,
+		HTTPClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// anthropicRequest represents the request body for /v1/messages.
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicResponse represents the response body from /v1/messages.
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// anthropicError represents the error body Anthropic returns.
+type anthropicError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SendChatMessage implements ChatProvider against Anthropic's Messages API.
+func (p *AnthropicProvider) SendChatMessage(ctx context.Context, systemPrompt, userMessage string, params ChatParams) (string, Usage, error) {
+	if p.APIKey == "" {
+		return "", Usage{}, &ProviderError{Type: "invalid_request", Message: "Anthropic API key not configured"}
+	}
+
+	model := params.Model
+	if model == "" {
+		model = anthropicModel
+	}
+
+	maxTokens := anthropicMaxTokens
+	if params.MaxTokens != nil {
+		maxTokens = *params.MaxTokens
+	} else if params.MaxCompletionTokens != nil {
+		maxTokens = *params.MaxCompletionTokens
+	}
+
+	request := anthropicRequest{
+		Model:       model,
+		System:      systemPrompt,
+		Messages:    []anthropicMessage{{Role: "user", Content: userMessage}},
+		MaxTokens:   maxTokens,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+	}
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", anthropicAPIURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", Usage{}, anthropicErrorFromBody(resp.StatusCode, body)
+	}
+
+	var anthropicResp anthropicResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(anthropicResp.Content) == 0 {
+		return "", Usage{}, &ProviderError{Status: resp.StatusCode, Type: "api_error", Message: "no response from Anthropic"}
+	}
+
+	usage := Usage{
+		PromptTokens:     anthropicResp.Usage.InputTokens,
+		CompletionTokens: anthropicResp.Usage.OutputTokens,
+		TotalTokens:      anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens,
+	}
+	return anthropicResp.Content[0].Text, usage, nil
+}
+
+// anthropicErrorFromBody normalizes an Anthropic error response body into a
+// ProviderError.
+func anthropicErrorFromBody(status int, body []byte) error {
+	var apiErr anthropicError
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error.Message != "" {
+		return &ProviderError{Status: status, Type: apiErr.Error.Type, Message: apiErr.Error.Message}
+	}
+	return &ProviderError{Status: status, Type: "api_error", Message: fmt.Sprintf("Anthropic API error: status %d", status)}
+}
@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AzureProvider sends chat completions to an Azure OpenAI deployment.
+// Unlike api.openai.com, Azure addresses a specific deployment via URL path
+// and authenticates with an api-key header rather than a Bearer token.
+type AzureProvider struct {
+	Endpoint   string // e.g. https://my-resource.openai.azure.com
+	APIKey     string
+	Deployment string
+	APIVersion string
+	HTTPClient *http.Client
+}
+
+// NewAzureProvider creates a provider for the given Azure OpenAI deployment.
+func NewAzureProvider(endpoint, apiKey, deployment, apiVersion string) *AzureProvider {
+	return &AzureProvider{
+		Endpoint:   endpoint,
+		APIKey:     apiKey,
+		Deployment: deployment,
+		APIVersion: apiVersion,
+		HTTPClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// SendChatMessage implements ChatProvider against Azure's chat completions
+// endpoint, which mirrors OpenAI's request/response shape.
+func (p *AzureProvider) SendChatMessage(ctx context.Context, systemPrompt, userMessage string, params ChatParams) (string, Usage, error) {
+	if p.Endpoint == "" || p.APIKey == "" || p.Deployment == "" {
+		return "", Usage{}, &ProviderError{Type: "invalid_request", Message: "Azure OpenAI is not configured"}
+	}
+
+	request := buildOpenAIRequestFromParams(params, systemPrompt, userMessage)
+	request.Model = "" // the deployment in the URL determines the model on Azure
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", p.Endpoint, p.Deployment, p.APIVersion)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("api-key", p.APIKey)
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", Usage{}, azureError(resp.StatusCode, body)
+	}
+
+	var openAIResp OpenAIResponse
+	if err := json.Unmarshal(body, &openAIResp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(openAIResp.Choices) == 0 {
+		return "", Usage{}, &ProviderError{Status: resp.StatusCode, Type: "api_error", Message: "no response from Azure OpenAI"}
+	}
+
+	usage := Usage{
+		PromptTokens:     openAIResp.Usage.PromptTokens,
+		CompletionTokens: openAIResp.Usage.CompletionTokens,
+		TotalTokens:      openAIResp.Usage.TotalTokens,
+	}
+	return openAIResp.Choices[0].Message.Content, usage, nil
+}
+
+// azureError normalizes an Azure OpenAI error response body into a
+// ProviderError.
+func azureError(status int, body []byte) error {
+	var openAIErr OpenAIError
+	if err := json.Unmarshal(body, &openAIErr); err == nil && openAIErr.Error.Message != "" {
+		return &ProviderError{Status: status, Type: openAIErr.Error.Type, Message: openAIErr.Error.Message}
+	}
+	return &ProviderError{Status: status, Type: "api_error", Message: fmt.Sprintf("Azure OpenAI error: status %d", status)}
+}
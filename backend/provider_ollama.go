@@ -0,0 +1,128 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const ollamaModel = "llama3"
+
+// OllamaProvider sends chat completions to a local (or self-hosted) Ollama
+// instance. Ollama has no concept of an API key; requests are addressed by
+// host alone.
+type OllamaProvider struct {
+	Host       string // e.g. http://localhost:11434
+	HTTPClient *http.Client
+}
+
+// NewOllamaProvider creates a provider pointed at the given Ollama host.
+func NewOllamaProvider(host string) *OllamaProvider {
+	return &OllamaProvider{
+		Host: host,
+		HTTPClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// ollamaRequest represents the request body for /api/chat.
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// ollamaResponse represents the (non-streamed) response body from /api/chat.
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// SendChatMessage implements ChatProvider against Ollama's /api/chat
+// endpoint. Ollama always returns a complete JSON object per request when
+// stream is false, regardless of the model.
+func (p *OllamaProvider) SendChatMessage(ctx context.Context, systemPrompt, userMessage string, params ChatParams) (string, Usage, error) {
+	if p.Host == "" {
+		return "", Usage{}, &ProviderError{Type: "invalid_request", Message: "Ollama host not configured"}
+	}
+
+	model := params.Model
+	if model == "" {
+		model = ollamaModel
+	}
+
+	var options *ollamaOptions
+	if params.Temperature != nil || params.TopP != nil {
+		options = &ollamaOptions{Temperature: params.Temperature, TopP: params.TopP}
+	}
+
+	request := ollamaRequest{
+		Model: model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		},
+		Stream:  false,
+		Options: options,
+	}
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.Host+"/api/chat", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || ollamaResp.Error != "" {
+		message := ollamaResp.Error
+		if message == "" {
+			message = fmt.Sprintf("Ollama error: status %d", resp.StatusCode)
+		}
+		return "", Usage{}, &ProviderError{Status: resp.StatusCode, Type: "api_error", Message: message}
+	}
+
+	usage := Usage{
+		PromptTokens:     ollamaResp.PromptEvalCount,
+		CompletionTokens: ollamaResp.EvalCount,
+		TotalTokens:      ollamaResp.PromptEvalCount + ollamaResp.EvalCount,
+	}
+	return ollamaResp.Message.Content, usage, nil
+}
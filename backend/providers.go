@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ChatProvider abstracts over the non-default LLM backends (Azure, Anthropic,
+// Ollama) so ChatWithHistory doesn't need a type switch to call them.
+// "openai" is handled separately by OpenAIClient.SendChatMessageWithHeaders,
+// which runs the history tool-calling loop these simpler providers don't -
+// they take an already-built prompt and return a single reply.
+type ChatProvider interface {
+	SendChatMessage(ctx context.Context, systemPrompt, userMessage string, params ChatParams) (string, Usage, error)
+}
+
+// ChatParams carries the generation parameters a caller may override,
+// independent of which provider ends up handling the request.
+type ChatParams struct {
+	Model               string
+	Temperature         *float64
+	TopP                *float64
+	MaxTokens           *int
+	MaxCompletionTokens *int
+	Seed                *int
+	ResponseFormat      *ResponseFormat
+	N                   *int
+	PresencePenalty     *float64
+	FrequencyPenalty    *float64
+}
+
+// Usage reports token accounting for a single completion.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// ProviderError normalizes the error shapes Azure, Anthropic, and Ollama
+// each return in their own format, so callers don't need a
+// provider-specific type switch to decide how to react. Errors from the
+// default "openai" path (OpenAIClient) are plain errors, not ProviderError,
+// and no provider sets RetryAfter.
+type ProviderError struct {
+	Status     int
+	Type       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: %s (status %d)", e.Type, e.Message, e.Status)
+}
+
+// chatParamsFromRequest extracts the provider-agnostic generation
+// parameters a ChatRequest carries.
+func chatParamsFromRequest(req *ChatRequest) ChatParams {
+	return ChatParams{
+		Model:               req.Model,
+		Temperature:         req.Temperature,
+		TopP:                req.TopP,
+		MaxTokens:           req.MaxTokens,
+		MaxCompletionTokens: req.MaxCompletionTokens,
+		Seed:                req.Seed,
+		ResponseFormat:      req.ResponseFormat,
+		N:                   req.N,
+		PresencePenalty:     req.PresencePenalty,
+		FrequencyPenalty:    req.FrequencyPenalty,
+	}
+}
+
+// defaultProviderName returns the configured fallback provider when a
+// ChatRequest doesn't specify one.
+func defaultProviderName() string {
+	if p := os.Getenv("DEFAULT_PROVIDER"); p != "" {
+		return p
+	}
+	return "openai"
+}
+
+// resolveProvider returns the ChatProvider named by req.Provider. Callers
+// are expected to have already routed the "openai" case (the configured
+// default, see defaultProviderName) to OpenAIClient.SendChatMessageWithHeaders
+// directly, since that's the only provider without a ChatProvider
+// implementation; "openai" here returns an unknown-provider error.
+func resolveProvider(req *ChatRequest) (ChatProvider, error) {
+	name := req.Provider
+	if name == "" {
+		name = defaultProviderName()
+	}
+
+	switch name {
+	case "azure":
+		return azureProvider, nil
+	case "anthropic":
+		return anthropicProvider, nil
+	case "ollama":
+		return ollamaProvider, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
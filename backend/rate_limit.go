@@ -15,6 +15,12 @@ type RateLimiter struct {
 	mu       sync.RWMutex
 	rate     rate.Limit
 	burst    int
+
+	// defaultRate is the configured steady-state rate to restore once
+	// upstream quota recovers. minRate is a floor so TightenForHeaders
+	// never throttles an IP down to zero.
+	defaultRate rate.Limit
+	minRate     rate.Limit
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -22,9 +28,11 @@ type RateLimiter struct {
 // burst: maximum burst size
 func NewRateLimiter(r float64, b int) *RateLimiter {
 	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
-		rate:     rate.Limit(r),
-		burst:    b,
+		limiters:    make(map[string]*rate.Limiter),
+		rate:        rate.Limit(r),
+		burst:       b,
+		defaultRate: rate.Limit(r),
+		minRate:     rate.Limit(r) / 10,
 	}
 }
 
@@ -48,6 +56,46 @@ func (rl *RateLimiter) Allow(ip string) bool {
 	return limiter.Allow()
 }
 
+// lowQuotaThreshold is how many requests OpenAI must have remaining before
+// the rate limiter starts tightening per-IP limits to absorb backpressure.
+const lowQuotaThreshold = 5
+
+// TightenForHeaders shrinks the per-IP rate limit once OpenAI reports it's
+// running low on quota, so client bursts queue locally instead of tripping
+// upstream 429s. When headers is nil or quota is back at or above the
+// threshold it restores the default rate; callers are expected to pass every
+// response's headers so limits loosen back up automatically.
+func (rl *RateLimiter) TightenForHeaders(headers *RateLimitHeaders) {
+	if headers == nil || headers.RemainingRequests >= lowQuotaThreshold {
+		rl.setRate(rl.defaultRate)
+		return
+	}
+
+	// Scale the per-IP rate down proportionally to how close we are to
+	// exhausting OpenAI's quota, with a floor so requests don't stall
+	// entirely.
+	fraction := float64(headers.RemainingRequests) / float64(lowQuotaThreshold)
+	tightened := rate.Limit(float64(rl.defaultRate) * fraction)
+	if tightened < rl.minRate {
+		tightened = rl.minRate
+	}
+	rl.setRate(tightened)
+}
+
+// setRate updates the shared rate and applies it to every existing limiter.
+func (rl *RateLimiter) setRate(r rate.Limit) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.rate == r {
+		return
+	}
+	rl.rate = r
+	for _, limiter := range rl.limiters {
+		limiter.SetLimit(r)
+	}
+}
+
 // cleanup removes old limiters to prevent memory leaks
 func (rl *RateLimiter) cleanup() {
 	rl.mu.Lock()
@@ -94,7 +142,7 @@ func RateLimitMiddleware(rl *RateLimiter) func(http.HandlerFunc) http.HandlerFun
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			ip := getClientIP(r)
-			
+
 			if !rl.Allow(ip) {
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusTooManyRequests)
@@ -106,4 +154,3 @@ func RateLimitMiddleware(rl *RateLimiter) func(http.HandlerFunc) http.HandlerFun
 		}
 	}
 }
-
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// streamDoneMarker is the sentinel OpenAI sends to end a text/event-stream
+// chat completion.
+const streamDoneMarker = "[DONE]"
+
+// SendChatMessageStream runs the same history tool-dispatch loop as
+// SendChatMessageWithHeaders - call OpenAI, execute any requested tools
+// locally, feed the results back - but once the model settles on a final
+// answer (no more tool calls), that last turn is re-issued with streaming
+// enabled so the reply is delivered to the caller token-by-token instead of
+// all at once. The returned RateLimitHeaders reflect whichever OpenAI
+// response was most recent, so callers can forward/tighten on quota the same
+// way the non-streaming path does.
+func (c *OpenAIClient) SendChatMessageStream(ctx context.Context, chatReq *ChatRequest) (<-chan StreamChunk, *RateLimitHeaders, error) {
+	if c.APIKey == "" {
+		return nil, nil, fmt.Errorf("OpenAI API key not configured")
+	}
+
+	systemPrompt := buildToolSystemPrompt(len(chatReq.History))
+	request := buildOpenAIRequest(chatReq, systemPrompt)
+	request.Tools = historyTools()
+	request.ToolChoice = "auto"
+
+	var lastHeaders *RateLimitHeaders
+
+	for i := 0; i < maxToolIterations; i++ {
+		openAIResp, rateLimitHeaders, err := c.doChatCompletion(ctx, request)
+		lastHeaders = rateLimitHeaders
+		if err != nil {
+			return nil, lastHeaders, err
+		}
+
+		if len(openAIResp.Choices) == 0 {
+			return nil, lastHeaders, fmt.Errorf("no response from OpenAI")
+		}
+
+		choice := openAIResp.Choices[0]
+		if choice.FinishReason != "tool_calls" || len(choice.Message.ToolCalls) == 0 {
+			request.Stream = true
+			chunks, streamHeaders, err := c.doChatCompletionStream(ctx, request)
+			if streamHeaders != nil {
+				lastHeaders = streamHeaders
+			}
+			return chunks, lastHeaders, err
+		}
+
+		request.Messages = append(request.Messages, choice.Message)
+		for _, call := range choice.Message.ToolCalls {
+			result, err := dispatchToolCall(call, chatReq.History)
+			if err != nil {
+				result = fmt.Sprintf(`{"error": %q}`, err.Error())
+			}
+			request.Messages = append(request.Messages, OpenAIMessage{
+				Role:       "tool",
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+
+	return nil, lastHeaders, fmt.Errorf("exceeded %d tool-calling iterations without a final answer", maxToolIterations)
+}
+
+// doChatCompletionStream sends request (with Stream already set to true) and
+// returns a channel of StreamChunk values as they arrive, plus the
+// rate-limit headers from the initial response. The channel is closed after
+// a chunk with Done set to true (or an error) is delivered, or without a Done
+// chunk when the response body ends before a [DONE] marker.
+func (c *OpenAIClient) doChatCompletionStream(ctx context.Context, request OpenAIRequest) (<-chan StreamChunk, *RateLimitHeaders, error) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", openAIAPIURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	rateLimitHeaders := parseRateLimitHeaders(resp.Header)
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var openAIErr OpenAIError
+		body := make([]byte, 0)
+		buf := make([]byte, 4096)
+		for {
+			n, readErr := resp.Body.Read(buf)
+			body = append(body, buf[:n]...)
+			if readErr != nil {
+				break
+			}
+		}
+		if err := json.Unmarshal(body, &openAIErr); err == nil && openAIErr.Error.Message != "" {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				return nil, rateLimitHeaders, fmt.Errorf("OpenAI rate limit exceeded. Please try again later")
+			}
+			return nil, rateLimitHeaders, fmt.Errorf("OpenAI API error: %s", openAIErr.Error.Message)
+		}
+		return nil, rateLimitHeaders, fmt.Errorf("OpenAI API error: status %d", resp.StatusCode)
+	}
+
+	chunks := make(chan StreamChunk)
+
+	go func() {
+		defer resp.Body.Close()
+		defer close(chunks)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if payload == streamDoneMarker {
+				chunks <- StreamChunk{Done: true}
+				return
+			}
+
+			var streamResp OpenAIStreamResponse
+			if err := json.Unmarshal([]byte(payload), &streamResp); err != nil {
+				chunks <- StreamChunk{Err: fmt.Errorf("failed to parse stream chunk: %w", err), Done: true}
+				return
+			}
+
+			if len(streamResp.Choices) == 0 {
+				continue
+			}
+
+			choice := streamResp.Choices[0]
+			chunks <- StreamChunk{
+				Content:      choice.Delta.Content,
+				FinishReason: choice.FinishReason,
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			chunks <- StreamChunk{Err: fmt.Errorf("failed to read stream: %w", err), Done: true}
+		}
+	}()
+
+	return chunks, rateLimitHeaders, nil
+}
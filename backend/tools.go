@@ -0,0 +1,187 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxToolIterations caps how many times SendChatMessageWithHeaders and
+// SendChatMessageStream will call OpenAI in the tool-dispatch loop before
+// giving up and returning an error.
+const maxToolIterations = 5
+
+// buildToolSystemPrompt creates the system prompt used when history is
+// exposed via tool calls rather than stuffed inline. entryCount lets the
+// model know roughly how much history it has available to query.
+func buildToolSystemPrompt(entryCount int) string {
+	return fmt.Sprintf(`You are a helpful assistant that helps users understand and explore their browser history.
+
+The user has %d browsing history entries available. You don't have them inline - use the search_history, get_visits_by_domain, and summarize_day tools to look up whatever is relevant to the user's question, then answer based on what you find. Be concise and helpful. If nothing relevant turns up, let the user know.`, entryCount)
+}
+
+// historyTools returns the tool definitions exposed to OpenAI so it can
+// query browsing history programmatically instead of the full history being
+// stuffed into the system prompt.
+func historyTools() []Tool {
+	return []Tool{
+		{
+			Type: "function",
+			Function: FunctionSpec{
+				Name:        "search_history",
+				Description: "Search the user's browsing history for entries matching a query, ranked by relevance.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{"type": "string", "description": "What to search for"},
+						"limit": map[string]interface{}{"type": "integer", "description": "Maximum number of results (default 10)"},
+					},
+					"required": []string{"query"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: FunctionSpec{
+				Name:        "get_visits_by_domain",
+				Description: "List visits to a specific domain, optionally restricted to visits since a given time.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"domain": map[string]interface{}{"type": "string", "description": "Domain to filter by, e.g. github.com"},
+						"since":  map[string]interface{}{"type": "string", "description": "RFC3339 timestamp; only visits after this are returned"},
+					},
+					"required": []string{"domain"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: FunctionSpec{
+				Name:        "summarize_day",
+				Description: "List all history entries last visited on a given calendar day.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"date": map[string]interface{}{"type": "string", "description": "Date in YYYY-MM-DD format"},
+					},
+					"required": []string{"date"},
+				},
+			},
+		},
+	}
+}
+
+// dispatchToolCall runs call against history and returns the JSON-encoded
+// result to feed back to OpenAI as a role:"tool" message.
+func dispatchToolCall(call ToolCall, history []HistoryEntry) (string, error) {
+	switch call.Function.Name {
+	case "search_history":
+		var args struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		}
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments for search_history: %w", err)
+		}
+		if args.Limit <= 0 {
+			args.Limit = 10
+		}
+		return marshalToolResult(searchHistory(args.Query, history, args.Limit))
+
+	case "get_visits_by_domain":
+		var args struct {
+			Domain string `json:"domain"`
+			Since  string `json:"since"`
+		}
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments for get_visits_by_domain: %w", err)
+		}
+		return marshalToolResult(visitsByDomain(args.Domain, args.Since, history))
+
+	case "summarize_day":
+		var args struct {
+			Date string `json:"date"`
+		}
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments for summarize_day: %w", err)
+		}
+		return marshalToolResult(summarizeDay(args.Date, history))
+
+	default:
+		return "", fmt.Errorf("unknown tool: %s", call.Function.Name)
+	}
+}
+
+func marshalToolResult(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool result: %w", err)
+	}
+	return string(b), nil
+}
+
+// searchHistory uses the retrieval index when available, falling back to a
+// case-insensitive substring match over titles and URLs.
+func searchHistory(query string, history []HistoryEntry, limit int) []HistoryEntry {
+	if historyIndex != nil {
+		if _, err := historyIndex.Ingest(history); err == nil {
+			if results, err := historyIndex.Retrieve(query, limit); err == nil {
+				return results
+			}
+		}
+	}
+
+	query = strings.ToLower(query)
+	matches := make([]HistoryEntry, 0, limit)
+	for _, entry := range history {
+		if strings.Contains(strings.ToLower(entry.Title), query) || strings.Contains(strings.ToLower(entry.URL), query) {
+			matches = append(matches, entry)
+			if len(matches) >= limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// visitsByDomain returns entries whose URL contains domain, optionally
+// restricted to visits at or after since (an RFC3339 timestamp), most
+// recent first.
+func visitsByDomain(domain, since string, history []HistoryEntry) []HistoryEntry {
+	var sinceTime time.Time
+	if since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			sinceTime = t
+		}
+	}
+
+	domain = strings.ToLower(domain)
+	matches := make([]HistoryEntry, 0)
+	for _, entry := range history {
+		if !strings.Contains(strings.ToLower(entry.URL), domain) {
+			continue
+		}
+		if !sinceTime.IsZero() && time.Unix(entry.LastVisitTime/1000, 0).Before(sinceTime) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].LastVisitTime > matches[j].LastVisitTime })
+	return matches
+}
+
+// summarizeDay returns entries last visited on the given YYYY-MM-DD date.
+func summarizeDay(date string, history []HistoryEntry) []HistoryEntry {
+	matches := make([]HistoryEntry, 0)
+	for _, entry := range history {
+		visitDate := time.Unix(entry.LastVisitTime/1000, 0).Format("2006-01-02")
+		if visitDate == date {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// dayMillis returns the LastVisitTime (ms since epoch) for noon local time on
+// the given calendar day, so summarizeDay's t.Format("2006-01-02") comparison
+// (which uses the local zone) matches regardless of the machine running the
+// test.
+func dayMillis(year int, month time.Month, day int) int64 {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.Local).UnixMilli()
+}
+
+func toolCall(id, name string, args interface{}) ToolCall {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		panic(err)
+	}
+	return ToolCall{
+		ID:   id,
+		Type: "function",
+		Function: ToolCallFunction{
+			Name:      name,
+			Arguments: string(raw),
+		},
+	}
+}
+
+// dispatchToolCall's search_history case goes through searchHistory, which
+// tries the embeddings-backed historyIndex first and falls back to a
+// substring match when that errors - which it always will here, since the
+// test environment has no OPENAI_API_KEY and NewOpenAIEmbedder.Embed refuses
+// to run without one. That makes the fallback path deterministic to assert
+// on without a live API call.
+func TestDispatchToolCall(t *testing.T) {
+	history := []HistoryEntry{
+		{URL: "https://go.dev/doc", Title: "The Go Programming Language", VisitCount: 3, LastVisitTime: dayMillis(2023, time.November, 16)},
+		{URL: "https://github.com/golang/go", Title: "golang/go: The Go programming language", VisitCount: 5, LastVisitTime: dayMillis(2023, time.November, 16)},
+		{URL: "https://news.ycombinator.com", Title: "Hacker News", VisitCount: 1, LastVisitTime: dayMillis(2023, time.November, 17)},
+	}
+
+	t.Run("search_history matches title and url", func(t *testing.T) {
+		result, err := dispatchToolCall(toolCall("1", "search_history", map[string]interface{}{"query": "go"}), history)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var entries []HistoryEntry
+		if err := json.Unmarshal([]byte(result), &entries); err != nil {
+			t.Fatalf("failed to unmarshal result: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+		}
+	})
+
+	t.Run("search_history respects limit", func(t *testing.T) {
+		result, err := dispatchToolCall(toolCall("1", "search_history", map[string]interface{}{"query": "go", "limit": 1}), history)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var entries []HistoryEntry
+		if err := json.Unmarshal([]byte(result), &entries); err != nil {
+			t.Fatalf("failed to unmarshal result: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1: %+v", len(entries), entries)
+		}
+	})
+
+	t.Run("search_history rejects invalid arguments", func(t *testing.T) {
+		call := ToolCall{ID: "1", Type: "function", Function: ToolCallFunction{Name: "search_history", Arguments: "not json"}}
+		if _, err := dispatchToolCall(call, history); err == nil {
+			t.Fatal("expected an error for invalid arguments, got nil")
+		}
+	})
+
+	t.Run("get_visits_by_domain filters by domain", func(t *testing.T) {
+		result, err := dispatchToolCall(toolCall("2", "get_visits_by_domain", map[string]interface{}{"domain": "github.com"}), history)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var entries []HistoryEntry
+		if err := json.Unmarshal([]byte(result), &entries); err != nil {
+			t.Fatalf("failed to unmarshal result: %v", err)
+		}
+		if len(entries) != 1 || entries[0].URL != "https://github.com/golang/go" {
+			t.Fatalf("got %+v, want a single github.com entry", entries)
+		}
+	})
+
+	t.Run("get_visits_by_domain rejects invalid arguments", func(t *testing.T) {
+		call := ToolCall{ID: "2", Type: "function", Function: ToolCallFunction{Name: "get_visits_by_domain", Arguments: "not json"}}
+		if _, err := dispatchToolCall(call, history); err == nil {
+			t.Fatal("expected an error for invalid arguments, got nil")
+		}
+	})
+
+	t.Run("summarize_day filters by calendar day", func(t *testing.T) {
+		date := time.Date(2023, time.November, 16, 0, 0, 0, 0, time.Local).Format("2006-01-02")
+		result, err := dispatchToolCall(toolCall("3", "summarize_day", map[string]interface{}{"date": date}), history)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var entries []HistoryEntry
+		if err := json.Unmarshal([]byte(result), &entries); err != nil {
+			t.Fatalf("failed to unmarshal result: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+		}
+	})
+
+	t.Run("summarize_day rejects invalid arguments", func(t *testing.T) {
+		call := ToolCall{ID: "3", Type: "function", Function: ToolCallFunction{Name: "summarize_day", Arguments: "not json"}}
+		if _, err := dispatchToolCall(call, history); err == nil {
+			t.Fatal("expected an error for invalid arguments, got nil")
+		}
+	})
+
+	t.Run("unknown tool returns an error", func(t *testing.T) {
+		call := ToolCall{ID: "4", Type: "function", Function: ToolCallFunction{Name: "delete_history", Arguments: "{}"}}
+		if _, err := dispatchToolCall(call, history); err == nil {
+			t.Fatal("expected an error for an unknown tool, got nil")
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package backend
 
+import "time"
+
 // HistoryEntry represents a single browsing history entry
 type HistoryEntry struct {
 	URL           string `json:"url"`
@@ -12,26 +14,121 @@ type HistoryEntry struct {
 type ChatRequest struct {
 	Message string         `json:"message"`
 	History []HistoryEntry `json:"history"`
+	// Stream requests token-by-token delivery over Server-Sent Events.
+	// Defaults to true; set to false to get a single JSON ChatResponse instead.
+	Stream *bool `json:"stream,omitempty"`
+
+	// Provider selects which LLM backend handles this request: "openai"
+	// (default), "azure", "anthropic", or "ollama". Falls back to the
+	// DEFAULT_PROVIDER env var, then "openai".
+	Provider string `json:"provider,omitempty"`
+
+	// Generation parameters below are optional overrides of the backend's
+	// defaults. When the request resolves to the "openai" provider, Model
+	// must appear in ALLOWED_MODELS (when that env var is set) and, for
+	// o1-series models, is subject to OpenAI's beta constraints - see
+	// validateChatRequest. Other providers skip both checks.
+	Model               string          `json:"model,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	MaxTokens           *int            `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int            `json:"max_completion_tokens,omitempty"`
+	Seed                *int            `json:"seed,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+	N                   *int            `json:"n,omitempty"`
+	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
+}
+
+// ResponseFormat requests a specific output format from OpenAI, e.g.
+// {"type": "json_object"}.
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// wantsStream reports whether the caller asked for SSE streaming.
+// Streaming is the default; callers opt out with "stream": false.
+func (r *ChatRequest) wantsStream() bool {
+	return r.Stream == nil || *r.Stream
 }
 
 // ChatResponse represents the response sent back to the frontend
 type ChatResponse struct {
-	Reply string `json:"reply,omitempty"`
-	Error string `json:"error,omitempty"`
+	Reply     string            `json:"reply,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	RateLimit *RateLimitHeaders `json:"rate_limit,omitempty"`
+}
+
+// RateLimitHeaders captures the `x-ratelimit-*` headers OpenAI returns on
+// every chat completion response, so the extension can show the caller how
+// much quota is left.
+type RateLimitHeaders struct {
+	LimitRequests     int           `json:"limit_requests"`
+	LimitTokens       int           `json:"limit_tokens"`
+	RemainingRequests int           `json:"remaining_requests"`
+	RemainingTokens   int           `json:"remaining_tokens"`
+	ResetRequests     time.Duration `json:"reset_requests"`
+	ResetTokens       time.Duration `json:"reset_tokens"`
 }
 
 // OpenAIMessage represents a message in the OpenAI chat format
 type OpenAIMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+
+	// ToolCalls is set on an assistant message when the model wants to call
+	// one or more tools instead of answering directly.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID identifies which ToolCall a role:"tool" message is
+	// responding to.
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
-// OpenAIRequest represents the request to OpenAI API
+// Tool describes a server-side function OpenAI can choose to call.
+type Tool struct {
+	Type     string       `json:"type"`
+	Function FunctionSpec `json:"function"`
+}
+
+// FunctionSpec is the JSON-schema description of a callable function, in
+// the shape OpenAI's function calling API expects.
+type FunctionSpec struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ToolCall is a single function invocation OpenAI asked the backend to run.
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction carries the name and JSON-encoded arguments of a ToolCall.
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// OpenAIRequest represents the request to OpenAI API. Generation parameters
+// are pointers so a caller-supplied zero value (e.g. temperature: 0) is
+// still sent to OpenAI instead of being dropped by omitempty.
 type OpenAIRequest struct {
-	Model       string          `json:"model"`
-	Messages    []OpenAIMessage `json:"messages"`
-	Temperature float64         `json:"temperature"`
-	MaxTokens   int             `json:"max_tokens"`
+	Model               string          `json:"model"`
+	Messages            []OpenAIMessage `json:"messages"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	MaxTokens           *int            `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int            `json:"max_completion_tokens,omitempty"`
+	Seed                *int            `json:"seed,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+	N                   *int            `json:"n,omitempty"`
+	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+	Tools               []Tool          `json:"tools,omitempty"`
+	ToolChoice          string          `json:"tool_choice,omitempty"`
 }
 
 // OpenAIChoice represents a single choice in the OpenAI response
@@ -64,3 +161,35 @@ type OpenAIError struct {
 	} `json:"error"`
 }
 
+// StreamChunk is a single piece of an in-progress assistant reply, sent to
+// the caller of SendChatMessageStream as OpenAI's response streams in.
+type StreamChunk struct {
+	Content      string `json:"content"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	Done         bool   `json:"done"`
+	Err          error  `json:"-"`
+}
+
+// OpenAIStreamDelta represents the incremental content of a streamed
+// OpenAI chat completion chunk.
+type OpenAIStreamDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// OpenAIStreamChoice represents a single choice within a streamed chunk.
+type OpenAIStreamChoice struct {
+	Delta        OpenAIStreamDelta `json:"delta"`
+	FinishReason string            `json:"finish_reason"`
+	Index        int               `json:"index"`
+}
+
+// OpenAIStreamResponse represents one "data: {...}" chunk of a
+// text/event-stream chat completion response.
+type OpenAIStreamResponse struct {
+	ID      string               `json:"id"`
+	Object  string               `json:"object"`
+	Created int64                `json:"created"`
+	Model   string               `json:"model"`
+	Choices []OpenAIStreamChoice `json:"choices"`
+}